@@ -5,6 +5,8 @@
 package modfile
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"strings"
 
@@ -14,6 +16,10 @@ import (
 // A SumFile is the parsed, interpreted form of a go.sum file.
 type SumFile struct {
 	Hash []*Hash
+
+	// Sorted, if true, tells FormatSumPatch to insert newly added
+	// entries in sorted position instead of appending them at the end.
+	Sorted bool
 }
 
 // A Hash is a single hash entry in a go.sum file.
@@ -103,6 +109,26 @@ func ParseSum(file string, data []byte) (*SumFile, error) {
 	return f, nil
 }
 
+// ParseSumOptions controls optional behavior of ParseSumOpts.
+type ParseSumOptions struct {
+	// DropBogus removes known-bogus empty-go.mod hash entries (see
+	// RemoveBogusHashes) as part of parsing.
+	DropBogus bool
+}
+
+// ParseSumOpts is like ParseSum but accepts options controlling optional
+// parsing behavior.
+func ParseSumOpts(file string, data []byte, opts ParseSumOptions) (*SumFile, error) {
+	f, err := ParseSum(file, data)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DropBogus {
+		f.RemoveBogusHashes()
+	}
+	return f, nil
+}
+
 // parseSumLine splits a go.sum line into its three fields:
 // module path, version, and hash.
 func parseSumLine(line string) (path, version, hash string, ok bool) {
@@ -178,6 +204,93 @@ func (f *SumFile) DropAll(mod module.Version) {
 	}
 }
 
+// Trim removes content (non-/go.mod) hash entries for modules not in
+// keep. It is meant for implementing "go mod tidy"-style pruning, where
+// keep is the final resolved build list: modules that no longer appear
+// in the build list don't need their zip contents verified, even though
+// their go.mod files may still be needed for MVS graph resolution (see
+// TrimGoMod). Entries are marked via the same cleared-entry mechanism as
+// DropHash, so a later call to Cleanup removes them from f.Hash.
+func (f *SumFile) Trim(keep map[module.Version]bool) {
+	for i := range f.Hash {
+		if !f.Hash[i].GoMod && !keep[f.Hash[i].Mod] {
+			f.Hash[i].Mod.Path = "" // mark for cleanup
+		}
+	}
+}
+
+// TrimGoMod removes go.mod hash entries for modules not in keep. keep is
+// typically the broader set of modules loaded for import resolution,
+// which can include modules that influence MVS but never end up in the
+// final build list trimmed by Trim. Entries are marked via the same
+// cleared-entry mechanism as DropHash, so a later call to Cleanup
+// removes them from f.Hash.
+func (f *SumFile) TrimGoMod(keep map[module.Version]bool) {
+	for i := range f.Hash {
+		if f.Hash[i].GoMod && !keep[f.Hash[i].Mod] {
+			f.Hash[i].Mod.Path = "" // mark for cleanup
+		}
+	}
+}
+
+// bogusGoModHash is the h1: hash of a single-file tree containing a
+// zero-length go.mod. Historically this hash was written into go.sum for
+// paths that turned out not to be modules at all (misspelled import
+// paths, or packages nested inside another module's tree), so it should
+// never appear in a valid go.sum.
+var bogusGoModHash = func() string {
+	empty := sha256.Sum256(nil)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%x  go.mod\n", empty)))
+	return "h1:" + base64.StdEncoding.EncodeToString(sum[:])
+}()
+
+// RemoveBogusHashes removes the "/go.mod" entries in f whose recorded
+// hash is the known-bogus empty-go.mod hash (see bogusGoModHash), and
+// returns the number of entries removed. Zip entries are never removed.
+func (f *SumFile) RemoveBogusHashes() int {
+	n := 0
+	for i := range f.Hash {
+		if f.Hash[i].GoMod && f.Hash[i].Hash == bogusGoModHash {
+			f.Hash[i].Mod.Path = "" // mark for cleanup
+			n++
+		}
+	}
+	f.Cleanup()
+	return n
+}
+
+// A VerifyError reports that a hash computed from module content does
+// not match the hash recorded in a SumFile.
+type VerifyError struct {
+	Mod   module.Version
+	GoMod bool
+	Have  string
+	Want  string
+}
+
+func (e *VerifyError) Error() string {
+	kind := "module"
+	if e.GoMod {
+		kind = "go.mod"
+	}
+	return fmt.Sprintf("%s@%s: %s hash mismatch: have %s, want %s", e.Mod.Path, e.Mod.Version, kind, e.Have, e.Want)
+}
+
+// Verify checks computedHash, the hash of mod's downloaded content (see
+// the dirhash package), against the entries recorded in f. If gomod is
+// true, computedHash is compared against mod's "/go.mod" entry;
+// otherwise it is compared against mod's zip entry. Verify returns nil
+// if f has no recorded entry for mod, since there is nothing to check
+// against, and a *VerifyError if a recorded entry disagrees.
+func (f *SumFile) Verify(mod module.Version, gomod bool, computedHash string) error {
+	for _, h := range f.Hash {
+		if h.Mod == mod && h.GoMod == gomod && h.Hash != computedHash {
+			return &VerifyError{Mod: mod, GoMod: gomod, Have: computedHash, Want: h.Hash}
+		}
+	}
+	return nil
+}
+
 // Cleanup cleans up the file after edit operations.
 // Modifications like DropHash clear the entry but do not remove it
 // from the slice. Cleanup removes all cleared entries.
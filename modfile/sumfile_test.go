@@ -323,6 +323,147 @@ func TestSumFormatSkipsClearedEntries(t *testing.T) {
 	}
 }
 
+func TestSumRemoveBogusHashes(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"example.com/notamodule v0.0.0-00010101000000-000000000000/go.mod " + bogusGoModHash + "\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := f.RemoveBogusHashes()
+	if n != 1 {
+		t.Fatalf("RemoveBogusHashes() = %d, want 1", n)
+	}
+
+	got := string(FormatSum(f))
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n"
+	if got != want {
+		t.Errorf("after RemoveBogusHashes:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Nothing bogus is left, so a second call removes nothing.
+	if n := f.RemoveBogusHashes(); n != 0 {
+		t.Errorf("second RemoveBogusHashes() = %d, want 0", n)
+	}
+}
+
+func TestParseSumOptsDropBogus(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"example.com/notamodule v0.0.0-00010101000000-000000000000/go.mod " + bogusGoModHash + "\n"
+
+	f, err := ParseSumOpts("go.sum", []byte(in), ParseSumOptions{DropBogus: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(FormatSum(f))
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n"
+	if got != want {
+		t.Errorf("ParseSumOpts(DropBogus):\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSumTrim(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n" +
+		"rsc.io/quote v1.5.2/go.mod h1:jkl012=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only golang.org/x/text is in the final build list; rsc.io/quote's
+	// go.mod was still needed for MVS graph resolution.
+	keep := map[module.Version]bool{
+		{Path: "golang.org/x/text", Version: "v0.3.0"}: true,
+	}
+	f.Trim(keep)
+	f.Cleanup()
+
+	got := string(FormatSum(f))
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2/go.mod h1:jkl012=\n"
+	if got != want {
+		t.Errorf("Trim:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSumTrimGoMod(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n" +
+		"rsc.io/quote v1.5.2/go.mod h1:jkl012=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither module participates in MVS graph resolution anymore.
+	f.TrimGoMod(map[module.Version]bool{})
+	f.Cleanup()
+
+	got := string(FormatSum(f))
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n"
+	if got != want {
+		t.Errorf("TrimGoMod:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSumVerify(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod := module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}
+
+	if err := f.Verify(mod, false, "h1:abc123="); err != nil {
+		t.Errorf("Verify(zip, matching) = %v, want nil", err)
+	}
+	if err := f.Verify(mod, true, "h1:def456="); err != nil {
+		t.Errorf("Verify(gomod, matching) = %v, want nil", err)
+	}
+
+	// A module with no recorded entry has nothing to verify against.
+	other := module.Version{Path: "rsc.io/quote", Version: "v1.5.2"}
+	if err := f.Verify(other, false, "h1:anything="); err != nil {
+		t.Errorf("Verify(no entry) = %v, want nil", err)
+	}
+}
+
+func TestSumVerifyMismatch(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod := module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}
+
+	err = f.Verify(mod, false, "h1:wrong=")
+	if err == nil {
+		t.Fatal("Verify(mismatch): got nil error, want error")
+	}
+	verr, ok := err.(*VerifyError)
+	if !ok {
+		t.Fatalf("Verify(mismatch) error type = %T, want *VerifyError", err)
+	}
+	if verr.Have != "h1:wrong=" || verr.Want != "h1:abc123=" {
+		t.Errorf("VerifyError = %+v, want Have=h1:wrong= Want=h1:abc123=", verr)
+	}
+}
+
 func TestSumAddThenFormat(t *testing.T) {
 	f := &SumFile{}
 	f.AddHash(module.Version{Path: "example.com/mod", Version: "v1.0.0"}, false, "h1:zip=")
@@ -0,0 +1,165 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"sync"
+
+	"github.com/wow-look-at-my/mod/module"
+)
+
+// sumAlt holds the zip and go.mod hash entries recorded for a single
+// module version. Most versions have at most one of each, but a
+// SumIndex keeps every alternate it has seen (for example, a go.sum that
+// records two different hashes for the same version) rather than
+// silently dropping one.
+type sumAlt struct {
+	Zip   []*Hash
+	GoMod []*Hash
+}
+
+// A SumIndex is an index over a SumFile's entries, built by
+// SumFile.Index, that makes Lookup and Has O(1) instead of the O(n)
+// scans AddHash, DropHash, and DropAll perform directly on f.Hash. It is
+// safe for concurrent readers, and for concurrent Add/Drop calls, via an
+// internal RWMutex. That safety covers calls made through the SumIndex
+// only: once a SumFile has been indexed, callers must make all further
+// reads and mutations of it through the SumIndex rather than mixing in
+// direct calls to SumFile's own methods (AddHash, DropHash, Trim, ...),
+// which take no lock and would race with it.
+//
+// A SumIndex keeps the underlying SumFile's Hash slice in sync as
+// entries are added or dropped through Add and Drop; it does not
+// observe changes made to the SumFile by other means (AddHash,
+// DropHash, Trim, ...) after Index was called.
+type SumIndex struct {
+	mu      sync.RWMutex
+	f       *SumFile
+	entries map[module.Version]*sumAlt
+}
+
+// Index builds a SumIndex over f's current, non-cleared entries.
+func (f *SumFile) Index() *SumIndex {
+	idx := &SumIndex{f: f, entries: make(map[module.Version]*sumAlt, len(f.Hash))}
+	for _, h := range f.Hash {
+		if h.Mod.Path == "" {
+			continue
+		}
+		idx.insert(h)
+	}
+	return idx
+}
+
+// insert adds h to the index. The caller must hold idx.mu for writing.
+func (idx *SumIndex) insert(h *Hash) {
+	e := idx.entries[h.Mod]
+	if e == nil {
+		e = &sumAlt{}
+		idx.entries[h.Mod] = e
+	}
+	if h.GoMod {
+		e.GoMod = append(e.GoMod, h)
+	} else {
+		e.Zip = append(e.Zip, h)
+	}
+}
+
+// altsLocked returns mod's recorded entries of the given kind. The
+// caller must hold idx.mu, for reading or writing.
+func (idx *SumIndex) altsLocked(mod module.Version, gomod bool) []*Hash {
+	e := idx.entries[mod]
+	if e == nil {
+		return nil
+	}
+	if gomod {
+		return e.GoMod
+	}
+	return e.Zip
+}
+
+// Lookup returns the hash recorded for mod, or ("", false) if there is
+// none. If gomod is true, it looks up the "/go.mod" hash; otherwise the
+// zip hash. If more than one alternate hash is recorded for mod, Lookup
+// returns the first one added.
+func (idx *SumIndex) Lookup(mod module.Version, gomod bool) (string, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	alts := idx.altsLocked(mod, gomod)
+	if len(alts) == 0 {
+		return "", false
+	}
+	return alts[0].Hash, true
+}
+
+// Has reports whether mod has a recorded hash of the given kind equal to
+// hash, checking every alternate if more than one is recorded.
+func (idx *SumIndex) Has(mod module.Version, gomod bool, hash string) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	for _, h := range idx.altsLocked(mod, gomod) {
+		if h.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Add records a new hash entry for mod in both the index and the
+// underlying SumFile, equivalent to SumFile.AddHash. If an identical
+// entry already exists, Add is a no-op.
+func (idx *SumIndex) Add(mod module.Version, gomod bool, hash string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, h := range idx.altsLocked(mod, gomod) {
+		if h.Hash == hash {
+			return // already present
+		}
+	}
+	version := mod.Version
+	if gomod {
+		version += "/go.mod"
+	}
+	h := &Hash{
+		Mod:   mod,
+		Hash:  hash,
+		GoMod: gomod,
+		Syntax: SumLine{
+			Path:    mod.Path,
+			Version: version,
+			Hash:    hash,
+			offset:  -1,
+		},
+	}
+	idx.f.Hash = append(idx.f.Hash, h)
+	idx.insert(h)
+}
+
+// Drop removes mod's recorded hash entries of the given kind from both
+// the index and the underlying SumFile. Dropped entries are marked via
+// the same cleared-entry mechanism as SumFile.DropHash, so a later call
+// to SumFile.Cleanup removes them from f.Hash.
+func (idx *SumIndex) Drop(mod module.Version, gomod bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	e := idx.entries[mod]
+	if e == nil {
+		return
+	}
+	alts := e.Zip
+	if gomod {
+		alts = e.GoMod
+	}
+	for _, h := range alts {
+		h.Mod.Path = "" // mark for cleanup
+	}
+	if gomod {
+		e.GoMod = nil
+	} else {
+		e.Zip = nil
+	}
+	if len(e.Zip) == 0 && len(e.GoMod) == 0 {
+		delete(idx.entries, mod)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wow-look-at-my/mod/module"
+)
+
+func TestSumIndexLookup(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := f.Index()
+
+	mod := module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}
+	if got, ok := idx.Lookup(mod, false); !ok || got != "h1:abc123=" {
+		t.Errorf("Lookup(zip) = %q, %v, want %q, true", got, ok, "h1:abc123=")
+	}
+	if got, ok := idx.Lookup(mod, true); !ok || got != "h1:def456=" {
+		t.Errorf("Lookup(gomod) = %q, %v, want %q, true", got, ok, "h1:def456=")
+	}
+
+	other := module.Version{Path: "rsc.io/quote", Version: "v9.9.9"}
+	if _, ok := idx.Lookup(other, false); ok {
+		t.Errorf("Lookup(missing) = _, true, want false")
+	}
+}
+
+func TestSumIndexHas(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := f.Index()
+	mod := module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}
+
+	if !idx.Has(mod, false, "h1:abc123=") {
+		t.Error("Has(matching) = false, want true")
+	}
+	if idx.Has(mod, false, "h1:wrong=") {
+		t.Error("Has(mismatch) = true, want false")
+	}
+	if idx.Has(mod, true, "h1:abc123=") {
+		t.Error("Has(wrong kind) = true, want false")
+	}
+}
+
+func TestSumIndexAdd(t *testing.T) {
+	f := &SumFile{}
+	idx := f.Index()
+	mod := module.Version{Path: "example.com/mod", Version: "v1.0.0"}
+
+	idx.Add(mod, false, "h1:zip=")
+	if len(f.Hash) != 1 {
+		t.Fatalf("after Add: len(f.Hash) = %d, want 1", len(f.Hash))
+	}
+	if got, ok := idx.Lookup(mod, false); !ok || got != "h1:zip=" {
+		t.Errorf("Lookup after Add = %q, %v, want %q, true", got, ok, "h1:zip=")
+	}
+
+	// Adding the same entry again is a no-op, in both the index and f.Hash.
+	idx.Add(mod, false, "h1:zip=")
+	if len(f.Hash) != 1 {
+		t.Fatalf("after duplicate Add: len(f.Hash) = %d, want 1", len(f.Hash))
+	}
+}
+
+func TestSumIndexDrop(t *testing.T) {
+	in := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n"
+
+	f, err := ParseSum("go.sum", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := f.Index()
+	mod := module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}
+
+	idx.Drop(mod, false)
+	f.Cleanup()
+
+	got := string(FormatSum(f))
+	want := "golang.org/x/text v0.3.0/go.mod h1:def456=\n"
+	if got != want {
+		t.Errorf("after Drop:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if _, ok := idx.Lookup(mod, false); ok {
+		t.Error("Lookup after Drop = _, true, want false")
+	}
+}
+
+func TestSumIndexConcurrent(t *testing.T) {
+	f := &SumFile{}
+	idx := f.Index()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		i := i
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			mod := module.Version{Path: fmt.Sprintf("example.com/mod%d", i), Version: "v1.0.0"}
+			idx.Add(mod, false, "h1:zip=")
+		}()
+		go func() {
+			defer wg.Done()
+			idx.Lookup(module.Version{Path: fmt.Sprintf("example.com/mod%d", i), Version: "v1.0.0"}, false)
+		}()
+	}
+	wg.Wait()
+}
+
+// synthSumFile returns a synthetic go.sum file with n modules (one zip
+// hash and one go.mod hash per module, 2n entries), for benchmarking.
+func synthSumFile(n int) *SumFile {
+	var buf strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "example.com/mod%d v1.0.0 h1:zip%d=\n", i, i)
+		fmt.Fprintf(&buf, "example.com/mod%d v1.0.0/go.mod h1:gomod%d=\n", i, i)
+	}
+	f, err := ParseSum("go.sum", []byte(buf.String()))
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func BenchmarkSumIndexLookup(b *testing.B) {
+	f := synthSumFile(10000)
+	idx := f.Index()
+	mod := module.Version{Path: "example.com/mod9999", Version: "v1.0.0"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Lookup(mod, false)
+	}
+}
+
+// BenchmarkSumFileLinearLookup scans f.Hash directly, the way AddHash
+// and DropHash do, as a baseline for BenchmarkSumIndexLookup.
+func BenchmarkSumFileLinearLookup(b *testing.B) {
+	f := synthSumFile(10000)
+	mod := module.Version{Path: "example.com/mod9999", Version: "v1.0.0"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, h := range f.Hash {
+			if h.Mod == mod && !h.GoMod {
+				break
+			}
+		}
+	}
+}
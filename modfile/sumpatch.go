@@ -0,0 +1,117 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatSumPatch returns orig, the original go.sum content f was parsed
+// from, patched to reflect f's current state: unchanged entries are
+// copied verbatim from orig using the byte offsets recorded in
+// f.Hash[i].Syntax, dropped entries (Mod.Path == "") are elided, and
+// newly added entries (an offset of -1) are appended at the end, or
+// inserted in sorted position if f.Sorted is set. Unlike FormatSum,
+// which reserializes every line, FormatSumPatch preserves the original
+// formatting and ordering of untouched lines, producing minimal diffs
+// when only a handful of entries change.
+func FormatSumPatch(orig []byte, f *SumFile) ([]byte, error) {
+	var kept, added []*Hash
+	for _, h := range f.Hash {
+		if h.Mod.Path == "" {
+			continue // dropped
+		}
+		if h.Syntax.offset == -1 {
+			added = append(added, h)
+		} else {
+			kept = append(kept, h)
+		}
+	}
+
+	lineFor := func(h *Hash) (string, error) {
+		if h.Syntax.offset == -1 {
+			return formatSumLine(h), nil
+		}
+		return sumLineAt(orig, h.Syntax.offset)
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Syntax.offset < kept[j].Syntax.offset })
+	var buf strings.Builder
+	if f.Sorted {
+		// Each added entry goes just before the first untouched entry
+		// that sorts after it; untouched entries keep their order.
+		sort.SliceStable(added, func(i, j int) bool { return sumSortKey(added[i]) < sumSortKey(added[j]) })
+		var all []*Hash
+		for _, h := range kept {
+			for len(added) > 0 && sumSortKey(added[0]) < sumSortKey(h) {
+				all, added = append(all, added[0]), added[1:]
+			}
+			all = append(all, h)
+		}
+		all = append(all, added...)
+		for _, h := range all {
+			line, err := lineFor(h)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+		return []byte(buf.String()), nil
+	}
+
+	for _, h := range kept {
+		line, err := lineFor(h)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	for _, h := range added {
+		buf.WriteString(formatSumLine(h))
+		buf.WriteByte('\n')
+	}
+	return []byte(buf.String()), nil
+}
+
+// sumLineAt extracts the raw line starting at offset in orig, verbatim
+// (including any leading or trailing whitespace), so that reformatting
+// an untouched entry is a byte-for-byte no-op.
+func sumLineAt(orig []byte, offset int) (string, error) {
+	if offset < 0 || offset > len(orig) {
+		return "", fmt.Errorf("modfile: offset %d out of range for %d-byte file", offset, len(orig))
+	}
+	rest := orig[offset:]
+	if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	return string(rest), nil
+}
+
+// formatSumLine formats h as a fresh go.sum line, in the same style as
+// FormatSum.
+func formatSumLine(h *Hash) string {
+	version := h.Mod.Version
+	if h.GoMod {
+		version += "/go.mod"
+	}
+	return fmt.Sprintf("%s %s %s", h.Mod.Path, version, h.Hash)
+}
+
+// sumSortKey returns the key FormatSumPatch sorts entries by when
+// f.Sorted is set: module path then version, compared byte-wise. A
+// "/go.mod" entry sorts after its zip entry, though not always directly
+// after it: "v1.0.0-rc1" sorts between "v1.0.0" and "v1.0.0/go.mod".
+func sumSortKey(h *Hash) string {
+	version := h.Mod.Version
+	if h.GoMod {
+		version += "/go.mod"
+	}
+	return h.Mod.Path + " " + version
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"testing"
+
+	"github.com/wow-look-at-my/mod/module"
+)
+
+func TestFormatSumPatchUnchanged(t *testing.T) {
+	in := []byte("golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n")
+
+	f, err := ParseSum("go.sum", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FormatSumPatch(in, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(in) {
+		t.Errorf("FormatSumPatch (no edits):\ngot:\n%s\nwant:\n%s", got, in)
+	}
+}
+
+func TestFormatSumPatchDrop(t *testing.T) {
+	in := []byte("golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n")
+
+	f, err := ParseSum("go.sum", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.DropAll(module.Version{Path: "rsc.io/quote", Version: "v1.5.2"})
+
+	got, err := FormatSumPatch(in, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n"
+	if string(got) != want {
+		t.Errorf("FormatSumPatch (drop):\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSumPatchAppendsAdded(t *testing.T) {
+	in := []byte("golang.org/x/text v0.3.0 h1:abc123=\n")
+
+	f, err := ParseSum("go.sum", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddHash(module.Version{Path: "rsc.io/quote", Version: "v1.5.2"}, false, "h1:ghi789=")
+
+	got, err := FormatSumPatch(in, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n"
+	if string(got) != want {
+		t.Errorf("FormatSumPatch (added, unsorted):\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSumPatchSortedInsertsInPlace(t *testing.T) {
+	in := []byte("golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n")
+
+	f, err := ParseSum("go.sum", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Sorted = true
+	f.AddHash(module.Version{Path: "golang.org/x/text", Version: "v0.3.0"}, true, "h1:def456=")
+
+	got, err := FormatSumPatch(in, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "golang.org/x/text v0.3.0 h1:abc123=\n" +
+		"golang.org/x/text v0.3.0/go.mod h1:def456=\n" +
+		"rsc.io/quote v1.5.2 h1:ghi789=\n"
+	if string(got) != want {
+		t.Errorf("FormatSumPatch (sorted insert):\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSumPatchPreservesOriginalWhitespace(t *testing.T) {
+	// The original line has trailing spaces that a full reserialization
+	// via FormatSum would drop; FormatSumPatch must preserve them
+	// verbatim for untouched entries so that diffs stay minimal.
+	in := []byte("golang.org/x/text v0.3.0 h1:abc123=   \n")
+
+	f, err := ParseSum("go.sum", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FormatSumPatch(in, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(in) {
+		t.Errorf("FormatSumPatch (trailing whitespace):\ngot:\n%q\nwant:\n%q", got, in)
+	}
+}
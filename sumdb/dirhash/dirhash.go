@@ -0,0 +1,144 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dirhash computes the "h1:" hashes recorded in go.sum files.
+//
+// The h1 algorithm is a hash-of-hashes: each file in a module tree is
+// hashed individually with SHA-256, the per-file hashes are formatted as
+// one line each (sorted by file name) and concatenated, and the
+// concatenation is hashed again with SHA-256 and base64-encoded. This
+// lets callers verify a module's content against a go.sum entry without
+// depending on cmd/go.
+package dirhash
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hash1 computes the "h1:" hash of the named files, reading each one via
+// open. Files are hashed in sorted order regardless of the order given.
+func hash1(files []string, open func(string) (io.ReadCloser, error)) (string, error) {
+	h := sha256.New()
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+	for _, file := range files {
+		if strings.Contains(file, "\n") {
+			return "", fmt.Errorf("dirhash: filenames with newlines are not supported")
+		}
+		r, err := open(file)
+		if err != nil {
+			return "", err
+		}
+		hf := sha256.New()
+		_, err = io.Copy(hf, r)
+		r.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%x  %s\n", hf.Sum(nil), file)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashGoMod returns the "h1:" hash of the go.mod file at gomodPath, as
+// recorded in go.sum entries with a "/go.mod" suffix. The hashed name is
+// always "go.mod", regardless of the file's actual path.
+func HashGoMod(gomodPath string) (string, error) {
+	return hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return os.Open(gomodPath)
+	})
+}
+
+// HashDir returns the "h1:" hash of the local directory tree rooted at
+// dir, as if it were the extracted module modPath@modVersion. Each file
+// name in the hash is prefixed with "modPath@modVersion/".
+func HashDir(dir, modPath, modVersion string) (string, error) {
+	prefix := modPath + "@" + modVersion
+	files, err := dirFiles(dir, prefix)
+	if err != nil {
+		return "", err
+	}
+	return hash1(files, func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, strings.TrimPrefix(name, prefix)))
+	})
+}
+
+// dirFiles returns the list of files in the tree rooted at dir, with dir
+// itself replaced by prefix in each name. Returned names always use
+// forward slashes.
+func dirFiles(dir, prefix string) ([]string, error) {
+	var files []string
+	dir = filepath.Clean(dir)
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		} else if file == dir {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+
+		rel := file
+		if dir != "." {
+			rel = file[len(dir)+1:]
+		}
+		f := filepath.Join(prefix, rel)
+		files = append(files, filepath.ToSlash(f))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// HashZip returns the "h1:" hash of the file content in the named module
+// zip file. Only file names and their contents are included in the
+// hash; the zip format's encoding, compression, and metadata are
+// ignored. Every entry in the zip must share the same
+// "modPath@modVersion/" prefix; HashZip rejects any entry that doesn't.
+func HashZip(zipPath string) (string, error) {
+	z, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer z.Close()
+
+	var prefix string
+	var files []string
+	zfiles := make(map[string]*zip.File)
+	for _, file := range z.File {
+		if prefix == "" {
+			// Module paths may contain slashes but not "@", and versions
+			// contain no slashes, so the prefix ends at the first slash
+			// after the "@".
+			if i := strings.Index(file.Name, "@"); i >= 0 {
+				if j := strings.Index(file.Name[i:], "/"); j >= 0 {
+					prefix = file.Name[:i+j+1]
+				}
+			}
+		}
+		if prefix == "" || !strings.HasPrefix(file.Name, prefix) {
+			return "", fmt.Errorf("dirhash: zip entry %q outside expected module@version/ prefix", file.Name)
+		}
+		files = append(files, file.Name)
+		zfiles[file.Name] = file
+	}
+	return hash1(files, func(name string) (io.ReadCloser, error) {
+		f := zfiles[name]
+		if f == nil {
+			return nil, fmt.Errorf("file %q not found in zip", name) // should never happen
+		}
+		return f.Open()
+	})
+}
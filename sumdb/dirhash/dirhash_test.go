@@ -0,0 +1,133 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dirhash
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashGoMod(t *testing.T) {
+	dir := t.TempDir()
+	gomod := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(gomod, []byte("module example.com/mod\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := HashGoMod(gomod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasPrefix(got, "h1:") {
+		t.Errorf("HashGoMod = %q, want h1: prefix", got)
+	}
+
+	// Hashing the same content under a different path gives the same hash,
+	// since the hashed name is always "go.mod".
+	other := filepath.Join(dir, "other.mod")
+	if err := os.WriteFile(other, []byte("module example.com/mod\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got2, err := HashGoMod(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != got2 {
+		t.Errorf("HashGoMod(other) = %q, want %q", got2, got)
+	}
+}
+
+func TestHashDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/mod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mod.go"), []byte("package mod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := HashDir(dir, "example.com/mod", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasPrefix(got, "h1:") {
+		t.Errorf("HashDir = %q, want h1: prefix", got)
+	}
+
+	// Hashing an identical tree a second time gives the same hash.
+	dir2 := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir2, "go.mod"), []byte("module example.com/mod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir2, "mod.go"), []byte("package mod\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got2, err := HashDir(dir2, "example.com/mod", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != got2 {
+		t.Errorf("HashDir(dir2) = %q, want %q", got2, got)
+	}
+}
+
+func TestHashZip(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "mod.zip")
+	writeZip(t, zipPath, map[string]string{
+		"example.com/mod@v1.0.0/go.mod": "module example.com/mod\n",
+		"example.com/mod@v1.0.0/mod.go": "package mod\n",
+	})
+
+	got, err := HashZip(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasPrefix(got, "h1:") {
+		t.Errorf("HashZip = %q, want h1: prefix", got)
+	}
+}
+
+func TestHashZipRejectsMixedPrefix(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "mod.zip")
+	writeZip(t, zipPath, map[string]string{
+		"example.com/mod@v1.0.0/go.mod": "module example.com/mod\n",
+		"other.com/mod@v1.0.0/mod.go":   "package mod\n",
+	})
+
+	if _, err := HashZip(zipPath); err == nil {
+		t.Fatal("HashZip with mixed prefixes: got nil error, want error")
+	}
+}
+
+func writeZip(t *testing.T, zipPath string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}